@@ -2,12 +2,14 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,6 +24,7 @@ const (
 	LUTC                      // if Ldate or Ltime is set, use UTC rather than the local time zone
 	Lmodule                   // module name
 	Llevel                    // the level of the log
+	Ljson                     // emit one JSON object per record instead of a human-readable line
 
 	LstdFlags = Ldate | Ltime | Lmicroseconds | Llevel // initial values for the standard logger
 )
@@ -66,52 +69,110 @@ func NewWriter(w io.Writer) Writer {
 	return writer{w: w}
 }
 
+// serializedWriter wraps a Writer so that the final WriteLog call,
+// the only part of Logger.output that must not run concurrently
+// with itself, is the sole serialized section.
+type serializedWriter struct {
+	mu sync.Mutex
+	w  Writer
+}
+
+func (sw *serializedWriter) WriteLog(t time.Time, level int, s []byte) {
+	sw.mu.Lock()
+	sw.w.WriteLog(t, level, s)
+	sw.mu.Unlock()
+}
+
 // - - - - - - - - - logger - - - - - - - - -
 
+// field is a structured key/value pair carried by a Logger built
+// with With, merged into each JSON record.
+type field struct {
+	key string
+	val interface{}
+}
+
+// loggerState holds the config a Logger and all of its With
+// children share live: a SetFlags/SetLevel/SetOutput/SetCallDepth
+// on any of them is visible to the rest immediately.
+type loggerState struct {
+	flag      atomic.Int32
+	level     atomic.Int32
+	out       atomic.Pointer[Writer]
+	calldepth atomic.Int32
+}
+
+// Logger reads its flag, level and calldepth lock-free via atomics,
+// so only the write to out is ever serialized. This trades a brief
+// window where an in-flight format may observe the previous flags
+// for a large win in throughput under concurrent logging.
 type Logger struct {
-	pool      *sync.Pool // a buf pool
-	flag      int
-	level     int
-	out       Writer
-	calldepth int
+	pool   *sync.Pool // a buf pool
+	state  *loggerState
+	fields []field // immutable; a child adds to a copy, see With
 }
 
 func NewLogger(w Writer, flag int, level int) *Logger {
-	return &Logger{
+	l := &Logger{
 		pool: &sync.Pool{
 			New: func() interface{} {
 				return bytes.NewBuffer(nil)
 			},
 		},
-		flag:      flag,
-		level:     level,
-		out:       w,
-		calldepth: 2,
+		state: &loggerState{},
+	}
+	l.state.flag.Store(int32(flag))
+	l.state.level.Store(int32(level))
+	l.state.calldepth.Store(2)
+	l.storeOutput(w)
+	return l
+}
+
+// NewJSONLogger returns a Logger whose output is one JSON object
+// per record (see Ljson) instead of a human-readable line.
+func NewJSONLogger(w Writer, level int) *Logger {
+	return NewLogger(w, Ljson, level)
+}
+
+func (l *Logger) storeOutput(w Writer) {
+	sw := Writer(&serializedWriter{w: w})
+	l.state.out.Store(&sw)
+}
+
+// With returns a child Logger that merges key/val, plus this
+// Logger's own fields, into every record's JSON object. The child
+// shares this Logger's state, so a later SetFlags/SetLevel/
+// SetOutput/SetCallDepth on either one is visible to both.
+func (l *Logger) With(key string, val interface{}) *Logger {
+	return &Logger{
+		pool:   l.pool,
+		state:  l.state,
+		fields: append(append([]field(nil), l.fields...), field{key: key, val: val}),
 	}
 }
 
 func (l *Logger) Flags() int {
-	return l.flag
+	return int(l.state.flag.Load())
 }
 
 func (l *Logger) SetFlags(flag int) {
-	l.flag = flag
+	l.state.flag.Store(int32(flag))
 }
 
 func (l *Logger) SetLevel(level int) {
-	l.level = level
+	l.state.level.Store(int32(level))
 }
 
 func (l *Logger) SetOutput(w Writer) {
-	l.out = w
+	l.storeOutput(w)
 }
 
 func (l *Logger) SetCallDepth(depth int) {
-	l.calldepth = depth
+	l.state.calldepth.Store(int32(depth))
 }
 
 func (l *Logger) CallDepth() int {
-	return l.calldepth
+	return int(l.state.calldepth.Load())
 }
 
 func itoa(buf *bytes.Buffer, i int, wid int) {
@@ -148,9 +209,9 @@ func moduleOf(file string) string {
 	return "UNKNOWN"
 }
 
-func (l *Logger) formatHeader(buf *bytes.Buffer, t time.Time, file string, line int, lvl int) {
-	if l.flag&(Ldate|Ltime|Lmicroseconds) != 0 {
-		if l.flag&Ldate != 0 {
+func (l *Logger) formatHeader(buf *bytes.Buffer, flag int, t time.Time, file string, line int, lvl int) {
+	if flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+		if flag&Ldate != 0 {
 			year, month, day := t.Date()
 			itoa(buf, year, 4)
 			buf.WriteByte('-')
@@ -159,32 +220,32 @@ func (l *Logger) formatHeader(buf *bytes.Buffer, t time.Time, file string, line
 			itoa(buf, day, 2)
 			buf.WriteByte(' ')
 		}
-		if l.flag&(Ltime|Lmicroseconds) != 0 {
+		if flag&(Ltime|Lmicroseconds) != 0 {
 			hour, min, sec := t.Clock()
 			itoa(buf, hour, 2)
 			buf.WriteByte(':')
 			itoa(buf, min, 2)
 			buf.WriteByte(':')
 			itoa(buf, sec, 2)
-			if l.flag&Lmicroseconds != 0 {
+			if flag&Lmicroseconds != 0 {
 				buf.WriteByte('.')
 				itoa(buf, t.Nanosecond()/1e6, 3)
 			}
 			buf.WriteByte(' ')
 		}
 	}
-	if l.flag&Llevel != 0 {
+	if flag&Llevel != 0 {
 		buf.WriteString(levels[lvl])
 		buf.WriteByte(' ')
 	}
-	if l.flag&Lmodule != 0 {
+	if flag&Lmodule != 0 {
 		buf.WriteByte('[')
 		buf.WriteString(moduleOf(file))
 		buf.WriteByte(']')
 		buf.WriteByte(' ')
 	}
-	if l.flag&(Lshortfile|Llongfile) != 0 {
-		if l.flag&Lshortfile != 0 {
+	if flag&(Lshortfile|Llongfile) != 0 {
+		if flag&Lshortfile != 0 {
 			short := file
 			for i := len(file) - 1; i > 0; i-- {
 				if file[i] == '/' {
@@ -201,13 +262,36 @@ func (l *Logger) formatHeader(buf *bytes.Buffer, t time.Time, file string, line
 	}
 }
 
+// formatJSON writes one JSON object for the record, merging in any
+// fields carried by a Logger built with With.
+func (l *Logger) formatJSON(buf *bytes.Buffer, t time.Time, file string, line int, lvl int, msg string) {
+	rec := make(map[string]interface{}, 6+len(l.fields))
+	rec["time"] = t.Format(time.RFC3339Nano)
+	rec["level"] = levels[lvl]
+	rec["module"] = moduleOf(file)
+	rec["file"] = file
+	rec["line"] = line
+	rec["msg"] = msg
+	for _, f := range l.fields {
+		rec[f.key] = f.val
+	}
+
+	enc, err := json.Marshal(rec)
+	if err != nil {
+		enc = []byte(fmt.Sprintf(`{"level":%q,"msg":%q}`, levels[lvl], err))
+	}
+	buf.Write(enc)
+	buf.WriteByte('\n')
+}
+
 func (l *Logger) output(lvl int, s string) {
 	now := time.Now() // get this early.
+	flag := int(l.state.flag.Load())
 	var file string
 	var line int
-	if l.flag&(Lshortfile|Llongfile) != 0 {
+	if flag&(Lshortfile|Llongfile|Ljson) != 0 {
 		var ok bool
-		_, file, line, ok = runtime.Caller(l.calldepth)
+		_, file, line, ok = runtime.Caller(int(l.state.calldepth.Load()))
 		if !ok {
 			file = "???"
 			line = 0
@@ -215,52 +299,57 @@ func (l *Logger) output(lvl int, s string) {
 	}
 	buf := l.pool.Get().(*bytes.Buffer)
 	buf.Reset()
-	l.formatHeader(buf, now, file, line, lvl)
-	buf.WriteString(s)
-	if len(s) > 0 && s[len(s)-1] != '\n' {
-		buf.WriteByte('\n')
+	if flag&Ljson != 0 {
+		l.formatJSON(buf, now, file, line, lvl, s)
+	} else {
+		l.formatHeader(buf, flag, now, file, line, lvl)
+		buf.WriteString(s)
+		if len(s) > 0 && s[len(s)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
 	}
-	l.out.WriteLog(now, lvl, buf.Bytes())
+	out := *l.state.out.Load()
+	out.WriteLog(now, lvl, buf.Bytes())
 	l.pool.Put(buf)
 }
 
 func (l *Logger) Debug(format string, v ...interface{}) {
-	if Ldebug < l.level {
+	if Ldebug < int(l.state.level.Load()) {
 		return
 	}
 	l.output(Ldebug, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Info(format string, v ...interface{}) {
-	if Linfo < l.level {
+	if Linfo < int(l.state.level.Load()) {
 		return
 	}
 	l.output(Linfo, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Notice(format string, v ...interface{}) {
-	if Lnotice < l.level {
+	if Lnotice < int(l.state.level.Load()) {
 		return
 	}
 	l.output(Lnotice, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Warn(format string, v ...interface{}) {
-	if Lwarn < l.level {
+	if Lwarn < int(l.state.level.Load()) {
 		return
 	}
 	l.output(Lwarn, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Error(format string, v ...interface{}) {
-	if Lerror < l.level {
+	if Lerror < int(l.state.level.Load()) {
 		return
 	}
 	l.output(Lerror, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) Panic(format string, v ...interface{}) {
-	if Lpanic < l.level {
+	if Lpanic < int(l.state.level.Load()) {
 		return
 	}
 	s := fmt.Sprintf(format, v...)
@@ -269,7 +358,7 @@ func (l *Logger) Panic(format string, v ...interface{}) {
 }
 
 func (l *Logger) Fatal(format string, v ...interface{}) {
-	if Lfatal < l.level {
+	if Lfatal < int(l.state.level.Load()) {
 		return
 	}
 	l.output(Lfatal, fmt.Sprintf(format, v...))
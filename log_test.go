@@ -0,0 +1,59 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/eachain/log"
+)
+
+func BenchmarkConcurrent(b *testing.B) {
+	logger := log.NewLogger(log.NewWriter(ioutil.Discard), log.LstdFlags, log.Linfo)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info("hello %s", "world")
+		}
+	})
+}
+
+func TestJSONLoggerWith(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewJSONLogger(log.NewWriter(&buf), log.Linfo)
+	logger = logger.With("service", "test")
+
+	logger.Info("hello %s", "world")
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("unmarshal record: %v", err)
+	}
+	if rec["msg"] != "hello world" {
+		t.Fatalf("msg = %v, want %q", rec["msg"], "hello world")
+	}
+	if rec["service"] != "test" {
+		t.Fatalf("service field = %v, want %q", rec["service"], "test")
+	}
+	if _, ok := rec["time"]; !ok {
+		t.Fatalf("record missing time field: %v", rec)
+	}
+}
+
+func TestLoggerWithSharesParentState(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewLogger(log.NewWriter(&buf), log.LstdFlags, log.Linfo)
+	child := logger.With("k", "v")
+
+	logger.SetLevel(log.Lwarn)
+	child.Info("dropped")
+	if buf.Len() != 0 {
+		t.Fatalf("expected child to observe parent's SetLevel, got %q", buf.String())
+	}
+
+	child.Warn("kept")
+	if buf.Len() == 0 {
+		t.Fatalf("expected child to emit at Lwarn")
+	}
+}
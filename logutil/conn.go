@@ -0,0 +1,199 @@
+package logutil
+
+import (
+	"errors"
+	"fmt"
+	glog "log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/eachain/log"
+)
+
+var errNoConn = errors.New("logutil: no connection")
+
+const (
+	connMinBackoff = 100 * time.Millisecond
+	connMaxBackoff = 30 * time.Second
+)
+
+// ConnOptions configures the writer returned by NewConnWriter.
+type ConnOptions struct {
+	// Reconnect redials the remote address on write error, with
+	// exponential backoff between attempts.
+	Reconnect bool
+
+	// ReconnectOnMsg opens a fresh connection per message and
+	// closes it afterwards, useful for UDP-style sinks.
+	ReconnectOnMsg bool
+
+	// DialTimeout bounds each dial. 0 means no timeout.
+	DialTimeout time.Duration
+
+	// WriteTimeout bounds each write. 0 means no timeout.
+	WriteTimeout time.Duration
+
+	// MinLevel drops records below this level.
+	MinLevel int
+
+	// ErrorHandler, if set, receives connection and write errors
+	// instead of them being logged to the standard glog output.
+	ErrorHandler func(error)
+
+	// QueueSize bounds how many records may be buffered while the
+	// writer is reconnecting. Defaults to 256.
+	QueueSize int
+}
+
+type connRecord struct {
+	t     time.Time
+	level int
+	msg   []byte
+}
+
+// connWriter ships records to a remote network sink. A single
+// goroutine owns the connection, so WriteLog itself only touches
+// the queue and is safe for concurrent use.
+type connWriter struct {
+	network string
+	addr    string
+	opts    ConnOptions
+
+	ch   chan *connRecord
+	pool sync.Pool
+
+	conn net.Conn
+}
+
+// NewConnWriter keeps a persistent connection to network/addr and
+// writes each log record to it. See ConnOptions for reconnect and
+// per-message redial behaviour.
+func NewConnWriter(network, addr string, opts ConnOptions) log.Writer {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 256
+	}
+
+	cw := &connWriter{
+		network: network,
+		addr:    addr,
+		opts:    opts,
+		ch:      make(chan *connRecord, opts.QueueSize),
+		pool: sync.Pool{
+			New: func() interface{} {
+				return &connRecord{}
+			},
+		},
+	}
+
+	if !opts.ReconnectOnMsg {
+		cw.conn, _ = cw.dial()
+	}
+
+	go cw.run()
+	return cw
+}
+
+func (cw *connWriter) reportError(err error) {
+	if cw.opts.ErrorHandler != nil {
+		cw.opts.ErrorHandler(err)
+		return
+	}
+	glog.Printf("conn writer: %v", err)
+}
+
+func (cw *connWriter) dial() (net.Conn, error) {
+	if cw.opts.DialTimeout > 0 {
+		return net.DialTimeout(cw.network, cw.addr, cw.opts.DialTimeout)
+	}
+	return net.Dial(cw.network, cw.addr)
+}
+
+func (cw *connWriter) WriteLog(t time.Time, level int, s []byte) {
+	if level < cw.opts.MinLevel {
+		return
+	}
+
+	rd := cw.pool.Get().(*connRecord)
+	rd.t = t
+	rd.level = level
+	rd.msg = append(rd.msg[:0], s...)
+
+	select {
+	case cw.ch <- rd:
+	default:
+		cw.pool.Put(rd)
+		cw.reportError(fmt.Errorf("conn writer: queue full, drop log"))
+	}
+}
+
+func (cw *connWriter) write(conn net.Conn, rd *connRecord) error {
+	if cw.opts.WriteTimeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(cw.opts.WriteTimeout))
+	}
+	_, err := conn.Write(rd.msg)
+	return err
+}
+
+// redial blocks until a connection is established, retrying with
+// exponential backoff. Only run's single goroutine ever touches
+// cw.conn, so no lock is needed here.
+func (cw *connWriter) redial() {
+	backoff := connMinBackoff
+	for {
+		conn, err := cw.dial()
+		if err == nil {
+			cw.conn = conn
+			return
+		}
+		cw.reportError(err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > connMaxBackoff {
+			backoff = connMaxBackoff
+		}
+	}
+}
+
+func (cw *connWriter) run() {
+	for rd := range cw.ch {
+		if cw.opts.ReconnectOnMsg {
+			conn, err := cw.dial()
+			if err != nil {
+				cw.reportError(err)
+			} else {
+				if err := cw.write(conn, rd); err != nil {
+					cw.reportError(err)
+				}
+				conn.Close()
+			}
+			cw.pool.Put(rd)
+			continue
+		}
+
+		if cw.conn == nil {
+			if !cw.opts.Reconnect {
+				cw.reportError(errNoConn)
+				cw.pool.Put(rd)
+				continue
+			}
+			cw.redial()
+		}
+
+		if err := cw.write(cw.conn, rd); err != nil {
+			cw.reportError(err)
+			cw.conn.Close()
+			cw.conn = nil
+			if cw.opts.Reconnect {
+				cw.redial()
+				if err := cw.write(cw.conn, rd); err != nil {
+					cw.reportError(err)
+					cw.conn.Close()
+					cw.conn = nil
+				}
+			}
+		}
+
+		cw.pool.Put(rd)
+	}
+}
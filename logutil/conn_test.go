@@ -0,0 +1,74 @@
+package logutil
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/eachain/log"
+)
+
+func TestConnWriterWritesToListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		n, _ := conn.Read(buf)
+		received <- buf[:n]
+	}()
+
+	w := NewConnWriter("tcp", ln.Addr().String(), ConnOptions{})
+	w.WriteLog(time.Now(), log.Linfo, []byte("hello\n"))
+
+	select {
+	case got := <-received:
+		if string(got) != "hello\n" {
+			t.Fatalf("listener got %q, want %q", got, "hello\n")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for conn writer to write")
+	}
+}
+
+func TestConnWriterDropsBelowMinLevel(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		n, _ := conn.Read(buf)
+		received <- buf[:n]
+	}()
+
+	w := NewConnWriter("tcp", ln.Addr().String(), ConnOptions{MinLevel: log.Lerror})
+	w.WriteLog(time.Now(), log.Linfo, []byte("dropped\n"))
+	w.WriteLog(time.Now(), log.Lerror, []byte("kept\n"))
+
+	select {
+	case got := <-received:
+		if string(got) != "kept\n" {
+			t.Fatalf("listener got %q, want only the Lerror record", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for conn writer to write")
+	}
+}
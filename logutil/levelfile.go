@@ -0,0 +1,72 @@
+package logutil
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/eachain/log"
+)
+
+// LevelAll is the perLevel map key for the file that receives
+// every record, regardless of level.
+const LevelAll = -1
+
+// LevelFileWriter dispatches each record to the file for its
+// level, optionally mirroring every record into an "all" file.
+// Each underlying *FileWriter is a FileSwitcher, so it can be
+// paired individually with WithInotify for rotation.
+type LevelFileWriter struct {
+	byLevel map[int]*FileWriter
+	all     *FileWriter
+}
+
+// NewLevelFileWriter opens filepath.Join(dir, perLevel[level]) for
+// each level, plus an optional perLevel[LevelAll] file receiving
+// every record. Files are created with MkdirAll like FileWriter.
+func NewLevelFileWriter(dir string, perLevel map[int]string) *LevelFileWriter {
+	lw := &LevelFileWriter{byLevel: make(map[int]*FileWriter, len(perLevel))}
+	for level, name := range perLevel {
+		fw := NewFileWriter(filepath.Join(dir, name))
+		if level == LevelAll {
+			lw.all = fw
+		} else {
+			lw.byLevel[level] = fw
+		}
+	}
+	return lw
+}
+
+// NewStdLevelFileWriter wires the standard per-level file names
+// (debug.log, info.log, ...), matching the levels table in package
+// log, plus an all.log receiving every record.
+func NewStdLevelFileWriter(dir string) *LevelFileWriter {
+	return NewLevelFileWriter(dir, map[int]string{
+		log.Ldebug:  "debug.log",
+		log.Linfo:   "info.log",
+		log.Lnotice: "notice.log",
+		log.Lwarn:   "warn.log",
+		log.Lerror:  "error.log",
+		log.Lpanic:  "panic.log",
+		log.Lfatal:  "fatal.log",
+		LevelAll:    "all.log",
+	})
+}
+
+// File returns the FileWriter for level, or the "all" file via
+// LevelAll, so it can be wrapped with WithInotify individually.
+// It returns nil if no file was configured for level.
+func (lw *LevelFileWriter) File(level int) *FileWriter {
+	if level == LevelAll {
+		return lw.all
+	}
+	return lw.byLevel[level]
+}
+
+func (lw *LevelFileWriter) WriteLog(t time.Time, level int, s []byte) {
+	if fw := lw.byLevel[level]; fw != nil {
+		fw.WriteLog(t, level, s)
+	}
+	if lw.all != nil {
+		lw.all.WriteLog(t, level, s)
+	}
+}
@@ -0,0 +1,67 @@
+package logutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eachain/log"
+)
+
+func TestLevelFileWriterDispatchesAndMirrorsAll(t *testing.T) {
+	dir := t.TempDir()
+
+	lw := NewLevelFileWriter(dir, map[int]string{
+		log.Lerror: "error.log",
+		log.Linfo:  "info.log",
+		LevelAll:   "all.log",
+	})
+
+	now := time.Now()
+	lw.WriteLog(now, log.Lerror, []byte("err\n"))
+	lw.WriteLog(now, log.Linfo, []byte("info\n"))
+
+	errData, err := os.ReadFile(filepath.Join(dir, "error.log"))
+	if err != nil {
+		t.Fatalf("read error.log: %v", err)
+	}
+	if string(errData) != "err\n" {
+		t.Fatalf("error.log = %q, want only the Lerror record", errData)
+	}
+
+	infoData, err := os.ReadFile(filepath.Join(dir, "info.log"))
+	if err != nil {
+		t.Fatalf("read info.log: %v", err)
+	}
+	if string(infoData) != "info\n" {
+		t.Fatalf("info.log = %q, want only the Linfo record", infoData)
+	}
+
+	allData, err := os.ReadFile(filepath.Join(dir, "all.log"))
+	if err != nil {
+		t.Fatalf("read all.log: %v", err)
+	}
+	if string(allData) != "err\ninfo\n" {
+		t.Fatalf("all.log = %q, want both records", allData)
+	}
+}
+
+func TestLevelFileWriterDropsUnconfiguredLevel(t *testing.T) {
+	dir := t.TempDir()
+
+	lw := NewLevelFileWriter(dir, map[int]string{log.Lerror: "error.log"})
+	lw.WriteLog(time.Now(), log.Lwarn, []byte("warn\n"))
+
+	if _, err := os.Stat(filepath.Join(dir, "warn.log")); !os.IsNotExist(err) {
+		t.Fatalf("expected no file for a level with no configured name")
+	}
+
+	errData, err := os.ReadFile(filepath.Join(dir, "error.log"))
+	if err != nil {
+		t.Fatalf("read error.log: %v", err)
+	}
+	if len(errData) != 0 {
+		t.Fatalf("error.log = %q, want the Lwarn record dropped", errData)
+	}
+}
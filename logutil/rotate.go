@@ -0,0 +1,175 @@
+package logutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const rotateDateFormat = "2006-01-02"
+
+// RotateOptions controls when a RotatingFileWriter rolls its file
+// and how many rotated files it keeps around.
+type RotateOptions struct {
+	// MaxSize is the max file size in bytes before rotating. 0 disables it.
+	MaxSize int64
+
+	// MaxLines is the max number of records before rotating. 0 disables it.
+	MaxLines int64
+
+	// Daily rotates the file at midnight, local time.
+	Daily bool
+
+	// MaxBackups is the max number of rotated files to retain.
+	// 0 keeps every rotated file.
+	MaxBackups int
+}
+
+// RotatingFileWriter is a log.Writer that writes to a file and
+// rotates it once MaxSize, MaxLines or Daily is triggered. The
+// current file is renamed with a timestamp suffix and a fresh
+// file is opened in its place; files beyond MaxBackups are removed.
+type RotatingFileWriter struct {
+	filename string
+	opts     RotateOptions
+
+	curSize  atomic.Int64
+	curLines atomic.Int64
+	openDay  atomic.Pointer[string] // local calendar date, rotateDateFormat
+
+	mu sync.Mutex
+	fp *os.File
+}
+
+// NewRotatingFileWriter opens filename (creating parent dirs as
+// needed) and returns a writer that rotates it per opts.
+func NewRotatingFileWriter(filename string, opts RotateOptions) *RotatingFileWriter {
+	dir := filepath.Dir(filename)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		panic(err)
+	}
+
+	rw := &RotatingFileWriter{filename: filename, opts: opts}
+	rw.openFile()
+	return rw
+}
+
+// dayOf returns t's local calendar date, so Daily rotates at local
+// midnight rather than at a raw Unix-epoch day boundary.
+func dayOf(t time.Time) string {
+	return t.In(time.Local).Format(rotateDateFormat)
+}
+
+func (rw *RotatingFileWriter) openFile() {
+	fp, err := os.OpenFile(rw.filename,
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0644)
+	if err != nil {
+		panic(err)
+	}
+
+	var size int64
+	if fi, err := fp.Stat(); err == nil {
+		size = fi.Size()
+	}
+
+	rw.fp = fp
+	rw.curSize.Store(size)
+	rw.curLines.Store(0)
+	day := dayOf(time.Now())
+	rw.openDay.Store(&day)
+}
+
+// Filename returns the file abs path.
+func (rw *RotatingFileWriter) Filename() string {
+	return rw.filename
+}
+
+func (rw *RotatingFileWriter) needRotate(t time.Time, n int) bool {
+	if rw.opts.Daily && dayOf(t) != *rw.openDay.Load() {
+		return true
+	}
+	if rw.opts.MaxSize > 0 && rw.curSize.Load() > 0 && rw.curSize.Load()+int64(n) > rw.opts.MaxSize {
+		return true
+	}
+	if rw.opts.MaxLines > 0 && rw.curLines.Load() >= rw.opts.MaxLines {
+		return true
+	}
+	return false
+}
+
+func (rw *RotatingFileWriter) WriteLog(t time.Time, level int, s []byte) {
+	if rw.needRotate(t, len(s)) {
+		rw.rotate(t, len(s))
+	}
+
+	rw.mu.Lock()
+	rw.fp.Write(s)
+	rw.mu.Unlock()
+
+	rw.curSize.Add(int64(len(s)))
+	rw.curLines.Add(1)
+}
+
+// backupName returns the next free rotated name for t, e.g.
+// app.log.2006-01-02.001.
+func (rw *RotatingFileWriter) backupName(t time.Time) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s.%s.%03d", rw.filename, t.Format(rotateDateFormat), i)
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+	}
+}
+
+func (rw *RotatingFileWriter) rotate(t time.Time, n int) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	// re-check under the lock: another goroutine may have
+	// already rotated while we were waiting for it.
+	if !rw.needRotate(t, n) {
+		return
+	}
+
+	old := rw.fp
+	backup := rw.backupName(t)
+	old.Close()
+	os.Rename(rw.filename, backup)
+
+	fp, err := os.OpenFile(rw.filename,
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0644)
+	if err != nil {
+		panic(err)
+	}
+
+	rw.fp = fp
+	rw.curSize.Store(0)
+	rw.curLines.Store(0)
+	day := dayOf(t)
+	rw.openDay.Store(&day)
+
+	rw.purgeOld()
+}
+
+func (rw *RotatingFileWriter) purgeOld() {
+	if rw.opts.MaxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(rw.filename + ".*")
+	if err != nil || len(matches) <= rw.opts.MaxBackups {
+		return
+	}
+
+	sort.Strings(matches)
+	for _, name := range matches[:len(matches)-rw.opts.MaxBackups] {
+		os.Remove(name)
+	}
+}
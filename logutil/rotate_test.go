@@ -0,0 +1,73 @@
+package logutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileWriterMaxLines(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	rw := NewRotatingFileWriter(filename, RotateOptions{MaxLines: 1, MaxBackups: 1})
+
+	now := time.Now()
+	rw.WriteLog(now, 0, []byte("line1\n"))
+	rw.WriteLog(now, 0, []byte("line2\n"))
+	rw.WriteLog(now, 0, []byte("line3\n"))
+
+	matches, err := filepath.Glob(filename + ".*")
+	if err != nil {
+		t.Fatalf("glob backups: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected MaxBackups to keep 1 rotated file, got %d: %v", len(matches), matches)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read current file: %v", err)
+	}
+	if string(data) != "line3\n" {
+		t.Fatalf("current file = %q, want only the latest line", data)
+	}
+}
+
+func TestRotatingFileWriterDaily(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	rw := NewRotatingFileWriter(filename, RotateOptions{Daily: true})
+
+	now := time.Now()
+	rw.WriteLog(now, 0, []byte("today\n"))
+
+	matches, err := filepath.Glob(filename + ".*")
+	if err != nil {
+		t.Fatalf("glob backups: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("expected no rotation within the same local day, got %v", matches)
+	}
+
+	tomorrow := now.Add(24 * time.Hour)
+	rw.WriteLog(tomorrow, 0, []byte("tomorrow\n"))
+
+	matches, err = filepath.Glob(filename + ".*")
+	if err != nil {
+		t.Fatalf("glob backups: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected rotation after crossing local midnight, got %d: %v", len(matches), matches)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read current file: %v", err)
+	}
+	if string(data) != "tomorrow\n" {
+		t.Fatalf("current file = %q, want only tomorrow's line", data)
+	}
+}
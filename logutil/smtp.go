@@ -0,0 +1,122 @@
+package logutil
+
+import (
+	"bytes"
+	glog "log"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eachain/log"
+)
+
+// SMTPConfig configures the writer returned by NewSMTPWriter.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       []string
+
+	// Subject is used verbatim as the email subject.
+	Subject string
+
+	// MinLevel is the lowest level that triggers an email. nil
+	// defaults to log.Lerror; since log.Ldebug == 0, a plain int
+	// can't distinguish "unset" from "page on everything".
+	MinLevel *int
+
+	// Throttle collapses a burst of records into a single email,
+	// flushed on this interval. 0 sends an email per record. A
+	// record at Lpanic or Lfatal always flushes immediately.
+	Throttle time.Duration
+
+	// ErrorHandler, if set, receives send failures instead of them
+	// being logged to the standard glog output.
+	ErrorHandler func(error)
+}
+
+// smtpWriter emails records at or above cfg.MinLevel, collapsing a
+// burst of them into a single email per cfg.Throttle.
+type smtpWriter struct {
+	cfg SMTPConfig
+
+	mu      sync.Mutex
+	pending bytes.Buffer
+	timer   *time.Timer
+}
+
+// NewSMTPWriter emails records at or above cfg.MinLevel (default
+// Lerror) to cfg.To via net/smtp.
+func NewSMTPWriter(cfg SMTPConfig) log.Writer {
+	if cfg.MinLevel == nil {
+		lvl := log.Lerror
+		cfg.MinLevel = &lvl
+	}
+	return &smtpWriter{cfg: cfg}
+}
+
+func (sw *smtpWriter) reportError(err error) {
+	if sw.cfg.ErrorHandler != nil {
+		sw.cfg.ErrorHandler(err)
+		return
+	}
+	glog.Printf("smtp writer: %v", err)
+}
+
+func (sw *smtpWriter) WriteLog(t time.Time, level int, s []byte) {
+	if level < *sw.cfg.MinLevel {
+		return
+	}
+
+	sw.mu.Lock()
+	sw.pending.Write(s)
+	needFlush := sw.cfg.Throttle <= 0 || level >= log.Lpanic
+	if !needFlush && sw.timer == nil {
+		sw.timer = time.AfterFunc(sw.cfg.Throttle, sw.flush)
+	}
+	sw.mu.Unlock()
+
+	if needFlush {
+		sw.flush()
+	}
+}
+
+func (sw *smtpWriter) flush() {
+	sw.mu.Lock()
+	if sw.pending.Len() == 0 {
+		sw.mu.Unlock()
+		return
+	}
+	body := append([]byte(nil), sw.pending.Bytes()...)
+	sw.pending.Reset()
+	if sw.timer != nil {
+		sw.timer.Stop()
+		sw.timer = nil
+	}
+	sw.mu.Unlock()
+
+	if err := sw.send(body); err != nil {
+		sw.reportError(err)
+	}
+}
+
+func (sw *smtpWriter) send(body []byte) error {
+	addr := sw.cfg.Host + ":" + sw.cfg.Port
+
+	var auth smtp.Auth
+	if sw.cfg.Username != "" {
+		auth = smtp.PlainAuth("", sw.cfg.Username, sw.cfg.Password, sw.cfg.Host)
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + sw.cfg.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(sw.cfg.To, ",") + "\r\n")
+	msg.WriteString("Subject: " + sw.cfg.Subject + "\r\n")
+	msg.WriteString("\r\n")
+	msg.Write(body)
+
+	return smtp.SendMail(addr, auth, sw.cfg.From, sw.cfg.To, msg.Bytes())
+}
@@ -0,0 +1,86 @@
+package logutil
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eachain/log"
+)
+
+// The writer always calls real net/smtp.SendMail; pointing it at a
+// port nothing listens on gives a fast, deterministic dial error, so
+// these tests observe the throttle/flush state machine via
+// ErrorHandler call counts without needing a real mail server.
+
+func TestSMTPWriterFlushesImmediatelyWithoutThrottle(t *testing.T) {
+	var sends int32
+	w := NewSMTPWriter(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: "1",
+		From: "a@example.com",
+		To:   []string{"b@example.com"},
+		ErrorHandler: func(error) {
+			atomic.AddInt32(&sends, 1)
+		},
+	})
+
+	w.WriteLog(time.Now(), log.Lerror, []byte("err1\n"))
+	w.WriteLog(time.Now(), log.Lerror, []byte("err2\n"))
+
+	if got := atomic.LoadInt32(&sends); got != 2 {
+		t.Fatalf("expected 2 immediate sends without Throttle, got %d", got)
+	}
+}
+
+func TestSMTPWriterThrottleCollapsesBurst(t *testing.T) {
+	var sends int32
+	lvl := log.Ldebug
+	w := NewSMTPWriter(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     "1",
+		From:     "a@example.com",
+		To:       []string{"b@example.com"},
+		MinLevel: &lvl,
+		Throttle: 50 * time.Millisecond,
+		ErrorHandler: func(error) {
+			atomic.AddInt32(&sends, 1)
+		},
+	})
+
+	w.WriteLog(time.Now(), log.Linfo, []byte("a\n"))
+	w.WriteLog(time.Now(), log.Linfo, []byte("b\n"))
+
+	if got := atomic.LoadInt32(&sends); got != 0 {
+		t.Fatalf("expected Throttle to delay the send, got %d immediately", got)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&sends); got != 1 {
+		t.Fatalf("expected Throttle to collapse the burst into 1 send, got %d", got)
+	}
+}
+
+func TestSMTPWriterDefaultMinLevel(t *testing.T) {
+	var sends int32
+	w := NewSMTPWriter(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: "1",
+		From: "a@example.com",
+		To:   []string{"b@example.com"},
+		ErrorHandler: func(error) {
+			atomic.AddInt32(&sends, 1)
+		},
+	})
+
+	w.WriteLog(time.Now(), log.Lwarn, []byte("below default\n"))
+	if got := atomic.LoadInt32(&sends); got != 0 {
+		t.Fatalf("expected default MinLevel (Lerror) to drop Lwarn, got %d sends", got)
+	}
+
+	w.WriteLog(time.Now(), log.Lerror, []byte("at default\n"))
+	if got := atomic.LoadInt32(&sends); got != 1 {
+		t.Fatalf("expected default MinLevel (Lerror) to let Lerror through, got %d sends", got)
+	}
+}